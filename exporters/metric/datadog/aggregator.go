@@ -0,0 +1,208 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datadog // import "go.opentelemetry.io/contrib/exporters/metric/datadog"
+
+import (
+	"context"
+	"sync"
+
+	"github.com/DataDog/sketches-go/ddsketch"
+
+	"go.opentelemetry.io/otel/api/metric"
+	export "go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/minmaxsumcount"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/sum"
+)
+
+// defaultSketchRelativeAccuracy is the relative-accuracy guarantee requested
+// from DDSketch when a sketchAggregator is built. This mirrors the default
+// the Datadog agent itself uses for client-side distribution sketches.
+const defaultSketchRelativeAccuracy = 0.01
+
+// sketchAggregator is an export.Aggregator backed directly by a DDSketch
+// built with the sketches-go v1.x store/mapping API, so that a Histogram
+// instrument can be exported to Datadog as a native distribution instead of
+// being approximated from a handful of fixed bucket boundaries.
+type sketchAggregator struct {
+	lock   sync.Mutex
+	kind   metric.NumberKind
+	sketch *ddsketch.DDSketch
+}
+
+var _ export.Aggregator = (*sketchAggregator)(nil)
+var _ aggregation.Distribution = (*sketchAggregator)(nil)
+
+func newSketch() *ddsketch.DDSketch {
+	sketch, err := ddsketch.NewDefaultDDSketch(defaultSketchRelativeAccuracy)
+	if err != nil {
+		// NewDefaultDDSketch only errors for an invalid accuracy, which
+		// can't happen with the constant above.
+		panic(err)
+	}
+	return sketch
+}
+
+// newSketchAggregators returns cnt new sketchAggregators for desc.
+func newSketchAggregators(cnt int, desc *metric.Descriptor) []sketchAggregator {
+	aggs := make([]sketchAggregator, cnt)
+	for i := range aggs {
+		aggs[i] = sketchAggregator{kind: desc.NumberKind(), sketch: newSketch()}
+	}
+	return aggs
+}
+
+// Aggregation returns an interface for reading the state of this aggregator.
+func (a *sketchAggregator) Aggregation() aggregation.Aggregation {
+	return a
+}
+
+// Kind returns aggregation.SketchKind.
+func (a *sketchAggregator) Kind() aggregation.Kind {
+	return aggregation.SketchKind
+}
+
+// Sum returns the sum of values in the checkpoint.
+func (a *sketchAggregator) Sum() (metric.Number, error) {
+	return a.toNumber(a.sketch.GetSum()), nil
+}
+
+// Count returns the number of values in the checkpoint.
+func (a *sketchAggregator) Count() (int64, error) {
+	return int64(a.sketch.GetCount()), nil
+}
+
+// Max returns the maximum value in the checkpoint.
+func (a *sketchAggregator) Max() (metric.Number, error) {
+	v, err := a.sketch.GetMaxValue()
+	if err != nil {
+		return 0, aggregation.ErrNoData
+	}
+	return a.toNumber(v), nil
+}
+
+// Min returns the minimum value in the checkpoint.
+func (a *sketchAggregator) Min() (metric.Number, error) {
+	v, err := a.sketch.GetMinValue()
+	if err != nil {
+		return 0, aggregation.ErrNoData
+	}
+	return a.toNumber(v), nil
+}
+
+// Quantile returns the estimated quantile of data in the checkpoint.
+// It is an error if `q` is less than 0 or greater than 1.
+func (a *sketchAggregator) Quantile(q float64) (metric.Number, error) {
+	if a.sketch.GetCount() == 0 {
+		return 0, aggregation.ErrNoData
+	}
+	v, err := a.sketch.GetValueAtQuantile(q)
+	if err != nil {
+		return 0, aggregation.ErrInvalidQuantile
+	}
+	return a.toNumber(v), nil
+}
+
+func (a *sketchAggregator) toNumber(f float64) metric.Number {
+	if a.kind == metric.Float64NumberKind {
+		return metric.NewFloat64Number(f)
+	}
+	return metric.NewInt64Number(int64(f))
+}
+
+// Update adds the recorded measurement to the current sketch.
+func (a *sketchAggregator) Update(_ context.Context, number metric.Number, desc *metric.Descriptor) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.sketch.Add(number.CoerceToFloat64(desc.NumberKind()))
+}
+
+// SynchronizedMove saves the current state into oa and resets the current
+// state to a new sketch, taking a lock to prevent concurrent Update() calls.
+func (a *sketchAggregator) SynchronizedMove(oa export.Aggregator, _ *metric.Descriptor) error {
+	o, _ := oa.(*sketchAggregator)
+	if o == nil {
+		return aggregator.NewInconsistentAggregatorError(a, oa)
+	}
+	replace := newSketch()
+
+	a.lock.Lock()
+	o.sketch, a.sketch = a.sketch, replace
+	a.lock.Unlock()
+
+	return nil
+}
+
+// Merge combines two sketches into one.
+func (a *sketchAggregator) Merge(oa export.Aggregator, _ *metric.Descriptor) error {
+	o, _ := oa.(*sketchAggregator)
+	if o == nil {
+		return aggregator.NewInconsistentAggregatorError(a, oa)
+	}
+	return a.sketch.MergeWith(o.sketch)
+}
+
+// sketchAggregatorSelector is an export.AggregatorSelector that hands out
+// sketchAggregators for Histogram-producing instruments, and the SDK's
+// ordinary sum aggregator for everything else. It is used in
+// place of go.opentelemetry.io/otel/sdk/metric/selector/simple's sketch
+// selector, which is built on sketches-go's pre-v1 flat API and is
+// therefore incompatible with the store/mapping API this exporter requires.
+type sketchAggregatorSelector struct{}
+
+var _ export.AggregatorSelector = sketchAggregatorSelector{}
+
+func (sketchAggregatorSelector) AggregatorFor(desc *metric.Descriptor, aggPtrs ...*export.Aggregator) {
+	switch desc.MetricKind() {
+	case metric.ValueObserverKind, metric.ValueRecorderKind:
+		aggs := newSketchAggregators(len(aggPtrs), desc)
+		for i := range aggPtrs {
+			*aggPtrs[i] = &aggs[i]
+		}
+	default:
+		aggs := sum.New(len(aggPtrs))
+		for i := range aggPtrs {
+			*aggPtrs[i] = &aggs[i]
+		}
+	}
+}
+
+// minmaxSumCountAggregatorSelector is the default, cheaper selector used
+// when Config.PreferExponentialHistograms is false: Histogram and
+// ValueObserver instruments get an inexpensive minmaxsumcount aggregator
+// instead of a per-series DDSketch. It mirrors
+// go.opentelemetry.io/otel/sdk/metric/selector/simple's
+// NewWithInexpensiveDistribution, reimplemented locally to avoid that
+// package's import of the sketches-go-pre-v1-incompatible
+// aggregator/ddsketch package.
+type minmaxSumCountAggregatorSelector struct{}
+
+var _ export.AggregatorSelector = minmaxSumCountAggregatorSelector{}
+
+func (minmaxSumCountAggregatorSelector) AggregatorFor(desc *metric.Descriptor, aggPtrs ...*export.Aggregator) {
+	switch desc.MetricKind() {
+	case metric.ValueObserverKind, metric.ValueRecorderKind:
+		aggs := minmaxsumcount.New(len(aggPtrs), desc)
+		for i := range aggPtrs {
+			*aggPtrs[i] = &aggs[i]
+		}
+	default:
+		aggs := sum.New(len(aggPtrs))
+		for i := range aggPtrs {
+			*aggPtrs[i] = &aggs[i]
+		}
+	}
+}
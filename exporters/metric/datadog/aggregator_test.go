@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datadog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/api/metric"
+	export "go.opentelemetry.io/otel/sdk/export/metric"
+)
+
+func TestSketchAggregatorUpdateAndCheckpoint(t *testing.T) {
+	desc := metric.NewDescriptor("test.histogram", metric.ValueRecorderKind, metric.Float64NumberKind)
+	aggs := newSketchAggregators(2, &desc)
+	agg, ckpt := &aggs[0], &aggs[1]
+
+	ctx := context.Background()
+	for _, v := range []float64{1, 2, 3, 4, 5} {
+		require.NoError(t, agg.Update(ctx, metric.NewFloat64Number(v), &desc))
+	}
+
+	require.NoError(t, agg.SynchronizedMove(ckpt, &desc))
+
+	count, err := ckpt.Count()
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, count)
+
+	// DDSketch is a lossy approximation (defaultSketchRelativeAccuracy), so
+	// compare against a tolerance proportional to that, not an exact value.
+	sum, err := ckpt.Sum()
+	require.NoError(t, err)
+	assert.InDelta(t, 15, sum.CoerceToFloat64(metric.Float64NumberKind), 15*defaultSketchRelativeAccuracy)
+
+	// SynchronizedMove resets agg to a fresh, empty sketch.
+	freshCount, err := agg.Count()
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, freshCount)
+}
+
+func TestSketchAggregatorMerge(t *testing.T) {
+	desc := metric.NewDescriptor("test.histogram", metric.ValueRecorderKind, metric.Float64NumberKind)
+	aggs := newSketchAggregators(2, &desc)
+	a, b := &aggs[0], &aggs[1]
+
+	ctx := context.Background()
+	require.NoError(t, a.Update(ctx, metric.NewFloat64Number(1), &desc))
+	require.NoError(t, b.Update(ctx, metric.NewFloat64Number(2), &desc))
+
+	require.NoError(t, a.Merge(b, &desc))
+
+	count, err := a.Count()
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+}
+
+func TestSketchAggregatorSelectorUsesSketchForValueRecorder(t *testing.T) {
+	desc := metric.NewDescriptor("test.histogram", metric.ValueRecorderKind, metric.Float64NumberKind)
+	var agg export.Aggregator
+	sketchAggregatorSelector{}.AggregatorFor(&desc, &agg)
+
+	_, ok := agg.(*sketchAggregator)
+	assert.True(t, ok, "expected a ValueRecorder to get a sketchAggregator")
+}
+
+func TestSketchAggregatorSelectorUsesSumForCounter(t *testing.T) {
+	desc := metric.NewDescriptor("test.counter", metric.CounterKind, metric.Int64NumberKind)
+	var agg export.Aggregator
+	sketchAggregatorSelector{}.AggregatorFor(&desc, &agg)
+
+	_, ok := agg.(*sketchAggregator)
+	assert.False(t, ok, "expected a Counter to not get a sketchAggregator")
+}
@@ -0,0 +1,87 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datadog // import "go.opentelemetry.io/contrib/exporters/metric/datadog"
+
+import (
+	export "go.opentelemetry.io/otel/sdk/export/metric"
+)
+
+// defaultStatsdAddr is the address dogstatsd listens on by default, both
+// for the Datadog Agent and the standalone dogstatsd binary.
+const defaultStatsdAddr = "localhost:8125"
+
+// Config holds options that shape how this exporter translates OTel
+// aggregations into Datadog payloads and where it sends them.
+type Config struct {
+	// StatsdAddr is the address of the dogstatsd listener (agent or
+	// standalone) this exporter submits metrics to.
+	StatsdAddr string
+
+	// PreferExponentialHistograms, when true, makes AggregatorSelector give
+	// ValueRecorder and ValueObserver instruments a DDSketch-backed
+	// aggregator (log-spaced bins) instead of a min/max/sum/count summary,
+	// so that their values are emitted to the agent as a native
+	// distribution. It does not select the SDK's exponential histogram
+	// aggregation, and the exporter has no export path for exponential
+	// histogram data points.
+	PreferExponentialHistograms bool
+}
+
+// Option applies a setting to Config.
+type Option func(*Config)
+
+// WithStatsdAddr sets the address of the dogstatsd listener metrics are
+// submitted to. The default is "localhost:8125".
+func WithStatsdAddr(addr string) Option {
+	return func(c *Config) {
+		c.StatsdAddr = addr
+	}
+}
+
+// WithExponentialHistograms sets PreferExponentialHistograms: when enabled,
+// ValueRecorder and ValueObserver instruments aggregate into a DDSketch so
+// that recorded values are exported as native distributions. The SDK's
+// exponential histogram aggregation is not used.
+func WithExponentialHistograms(enabled bool) Option {
+	return func(c *Config) {
+		c.PreferExponentialHistograms = enabled
+	}
+}
+
+func newConfig(opts ...Option) Config {
+	c := Config{StatsdAddr: defaultStatsdAddr}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// AggregatorSelector returns the export.AggregatorSelector this Config
+// advises: a sketchAggregatorSelector when PreferExponentialHistograms is
+// set, so Histogram instruments are exported as native DDSketch
+// distributions, or minmaxSumCountAggregatorSelector, a local inexpensive
+// selector, otherwise.
+func (c Config) AggregatorSelector() export.AggregatorSelector {
+	if c.PreferExponentialHistograms {
+		return sketchAggregatorSelector{}
+	}
+	// go.opentelemetry.io/otel/sdk/metric/selector/simple's selectors are
+	// deliberately not used here: every one of them, including
+	// NewWithInexpensiveDistribution, imports
+	// go.opentelemetry.io/otel/sdk/metric/aggregator/ddsketch, which is
+	// built against sketches-go's pre-v1 flat API and does not compile
+	// against the store/mapping API this exporter requires.
+	return minmaxSumCountAggregatorSelector{}
+}
@@ -0,0 +1,193 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datadog // import "go.opentelemetry.io/contrib/exporters/metric/datadog"
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/DataDog/datadog-go/statsd"
+	"github.com/DataDog/sketches-go/ddsketch"
+
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/label"
+	export "go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/controller/push"
+	"go.opentelemetry.io/otel/sdk/metric/processor/basic"
+)
+
+// Exporter pushes checkpointed metrics to a dogstatsd listener (typically
+// the Datadog Agent) over UDP.
+type Exporter struct {
+	client *statsd.Client
+	config Config
+
+	// ExportKindSelector is embedded so Exporter satisfies export.Exporter.
+	// PassThroughExporter is correct here because this exporter never
+	// accumulates state across collection intervals itself; it forwards
+	// whichever kind of aggregation the SDK already computed.
+	export.ExportKindSelector
+}
+
+var _ export.Exporter = (*Exporter)(nil)
+
+// NewExporter creates a new Exporter that submits metrics to the dogstatsd
+// listener addressed by opts (or "localhost:8125" by default).
+func NewExporter(opts ...Option) (*Exporter, error) {
+	config := newConfig(opts...)
+
+	client, err := statsd.New(config.StatsdAddr)
+	if err != nil {
+		return nil, fmt.Errorf("datadog: creating statsd client: %w", err)
+	}
+
+	return &Exporter{
+		client:             client,
+		config:             config,
+		ExportKindSelector: export.PassThroughExporter,
+	}, nil
+}
+
+// NewExportPipeline creates a push Controller that periodically checkpoints
+// metrics recorded against its Meter and submits them to Datadog through a
+// new Exporter. Call Start on the returned Controller to begin collection,
+// and Stop to flush and release its resources. pushOpts configure the
+// Controller itself (e.g. push.WithPeriod); opts configure the Exporter.
+func NewExportPipeline(pushOpts []push.Option, opts ...Option) (*push.Controller, error) {
+	exporter, err := NewExporter(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	processor := basic.New(exporter.config.AggregatorSelector(), exporter)
+	return push.New(processor, exporter, pushOpts...), nil
+}
+
+// Export forwards every record in checkpointSet to the dogstatsd listener,
+// translating each aggregation into the Datadog metric type that best
+// preserves it: sums become statsd counts, last values become gauges,
+// DDSketch-backed aggregations become statsd distributions built by
+// replaying each bin's representative value, and other distributions and
+// min/max/sum/count aggregations become .count/.sum/.min/.max gauges.
+func (e *Exporter) Export(_ context.Context, checkpointSet export.CheckpointSet) error {
+	return checkpointSet.ForEach(e, func(record export.Record) error {
+		desc := record.Descriptor()
+		name := desc.Name()
+		tags := tagsFromLabels(record.Labels())
+		agg := record.Aggregation()
+
+		switch a := agg.(type) {
+		case *sketchAggregator:
+			return e.exportSketch(name, tags, a.sketch)
+		case aggregation.Distribution:
+			return e.exportDistributionSummary(name, tags, desc.NumberKind(), a)
+		case aggregation.MinMaxSumCount:
+			return e.exportMinMaxSumCount(name, tags, desc.NumberKind(), a)
+		case aggregation.LastValue:
+			v, _, err := a.LastValue()
+			if err != nil {
+				return err
+			}
+			return e.client.Gauge(name, v.CoerceToFloat64(desc.NumberKind()), tags, 1)
+		case aggregation.Sum:
+			v, err := a.Sum()
+			if err != nil {
+				return err
+			}
+			return e.client.Count(name, int64(v.CoerceToFloat64(desc.NumberKind())), tags, 1)
+		default:
+			return nil
+		}
+	})
+}
+
+// exportSketch submits every populated bin of sketch to the agent as a
+// distribution. datadog-go's statsd client has no API for submitting a
+// pre-built sketch directly, so each bin's representative value is
+// replayed once per recorded count; this reproduces the same distribution
+// the agent would build from uncompressed samples.
+func (e *Exporter) exportSketch(name string, tags []string, sketch *ddsketch.DDSketch) error {
+	var exportErr error
+	sketch.ForEach(func(value, count float64) bool {
+		for i := 0; i < int(count); i++ {
+			if err := e.client.Distribution(name, value, tags, 1); err != nil {
+				exportErr = err
+				return true
+			}
+		}
+		return false
+	})
+	return exportErr
+}
+
+// exportDistributionSummary submits the summary statistics of a
+// Distribution aggregation that isn't backed by a live DDSketch (e.g. a
+// custom aggregator supplied by an application). It cannot replay the
+// underlying samples, so it reports the same Min/Max/Sum/Count gauges as
+// exportMinMaxSumCount.
+func (e *Exporter) exportDistributionSummary(name string, tags []string, kind metric.NumberKind, d aggregation.Distribution) error {
+	return e.exportMinMaxSumCount(name, tags, kind, d)
+}
+
+func (e *Exporter) exportMinMaxSumCount(name string, tags []string, kind metric.NumberKind, mmsc aggregation.MinMaxSumCount) error {
+	count, err := mmsc.Count()
+	if err != nil {
+		return err
+	}
+	if err := e.client.Gauge(name+".count", float64(count), tags, 1); err != nil {
+		return err
+	}
+
+	sum, err := mmsc.Sum()
+	if err != nil {
+		return err
+	}
+	if err := e.client.Gauge(name+".sum", sum.CoerceToFloat64(kind), tags, 1); err != nil {
+		return err
+	}
+
+	min, err := mmsc.Min()
+	if err == nil {
+		if err := e.client.Gauge(name+".min", min.CoerceToFloat64(kind), tags, 1); err != nil {
+			return err
+		}
+	} else if err != aggregation.ErrNoData {
+		return err
+	}
+
+	max, err := mmsc.Max()
+	if err == nil {
+		if err := e.client.Gauge(name+".max", max.CoerceToFloat64(kind), tags, 1); err != nil {
+			return err
+		}
+	} else if err != aggregation.ErrNoData {
+		return err
+	}
+
+	return nil
+}
+
+// tagsFromLabels converts an OTel label set into the "key:value" tag
+// strings the statsd client expects.
+func tagsFromLabels(labels *label.Set) []string {
+	tags := make([]string, 0, labels.Len())
+	iter := labels.Iter()
+	for iter.Next() {
+		kv := iter.Label()
+		tags = append(tags, fmt.Sprintf("%s:%s", kv.Key, kv.Value.Emit()))
+	}
+	return tags
+}
@@ -0,0 +1,93 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package echo // import "go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo"
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentationName = "go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo"
+)
+
+// RouteAttributeExtractor customizes the http.route attribute recorded on
+// spans and metrics for a request. It receives the Echo context after the
+// handler chain has run, so c.Path() already reflects the matched route
+// template. Implementations that mount subrouters or rewrite paths at
+// runtime can use this to normalize routes and avoid unbounded cardinality
+// on the http.route attribute.
+type RouteAttributeExtractor func(c echo.Context) string
+
+// config is the configuration for the Echo middleware.
+type config struct {
+	TracerProvider trace.TracerProvider
+	MeterProvider  metric.MeterProvider
+	Skipper        Skipper
+	RouteExtractor RouteAttributeExtractor
+}
+
+// Skipper defines a function to skip middleware logic for a given request.
+type Skipper func(c echo.Context) bool
+
+// Option specifies instrumentation configuration options.
+type Option func(*config)
+
+// WithTracerProvider specifies a tracer provider to use for creating a
+// tracer. If none is specified, the global provider is used.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(cfg *config) {
+		if provider != nil {
+			cfg.TracerProvider = provider
+		}
+	}
+}
+
+// WithMeterProvider specifies a meter provider to use for creating a meter.
+// If none is specified, the global provider is used.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(cfg *config) {
+		if provider != nil {
+			cfg.MeterProvider = provider
+		}
+	}
+}
+
+// WithSkipper specifies a function to skip the middleware for certain
+// requests, for example health checks.
+func WithSkipper(skipper Skipper) Option {
+	return func(cfg *config) {
+		if skipper != nil {
+			cfg.Skipper = skipper
+		}
+	}
+}
+
+// WithRouteAttributeExtractor overrides how the http.route attribute is
+// derived for a request. The default extractor uses c.Path(), Echo's
+// matched route template.
+func WithRouteAttributeExtractor(extractor RouteAttributeExtractor) Option {
+	return func(cfg *config) {
+		if extractor != nil {
+			cfg.RouteExtractor = extractor
+		}
+	}
+}
+
+func defaultRouteAttributeExtractor(c echo.Context) string {
+	return c.Path()
+}
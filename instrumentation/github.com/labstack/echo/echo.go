@@ -0,0 +1,195 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package echo instruments the labstack/echo/v4 package.
+package echo // import "go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo"
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName = instrumentationName
+	meterName  = instrumentationName
+)
+
+// instruments bundles the metrics this middleware records. They are
+// shared across all requests handled by a single Middleware instance.
+type instruments struct {
+	requestDuration metric.Float64Histogram
+	requestSize     metric.Int64Histogram
+	responseSize    metric.Int64Histogram
+	activeRequests  metric.Int64UpDownCounter
+}
+
+func newInstruments(meter metric.Meter) (*instruments, error) {
+	requestDuration, err := meter.Float64Histogram(
+		"http.server.duration",
+		metric.WithDescription("Measures the duration of inbound HTTP requests."),
+		metric.WithUnit("ms"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	requestSize, err := meter.Int64Histogram(
+		"http.server.request.size",
+		metric.WithDescription("Measures the size of HTTP request bodies."),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	responseSize, err := meter.Int64Histogram(
+		"http.server.response.size",
+		metric.WithDescription("Measures the size of HTTP response bodies."),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	activeRequests, err := meter.Int64UpDownCounter(
+		"http.server.active_requests",
+		metric.WithDescription("Measures the number of concurrent HTTP requests in flight."),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &instruments{
+		requestDuration: requestDuration,
+		requestSize:     requestSize,
+		responseSize:    responseSize,
+		activeRequests:  activeRequests,
+	}, nil
+}
+
+// Middleware returns an echo middleware which will trace incoming requests.
+// It creates spans named after the matched route, and, unless the meter
+// provider resolves to a no-op implementation, records standard HTTP server
+// metrics for every request.
+func Middleware(service string, opts ...Option) echo.MiddlewareFunc {
+	cfg := config{
+		RouteExtractor: defaultRouteAttributeExtractor,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.TracerProvider == nil {
+		cfg.TracerProvider = otel.GetTracerProvider()
+	}
+	if cfg.MeterProvider == nil {
+		cfg.MeterProvider = otel.GetMeterProvider()
+	}
+
+	tracer := cfg.TracerProvider.Tracer(
+		tracerName,
+		trace.WithInstrumentationVersion(Version()),
+	)
+	meter := cfg.MeterProvider.Meter(
+		meterName,
+		metric.WithInstrumentationVersion(Version()),
+	)
+
+	instr, err := newInstruments(meter)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if cfg.Skipper != nil && cfg.Skipper(c) {
+				return next(c)
+			}
+
+			request := c.Request()
+			savedCtx := request.Context()
+
+			ctx := otel.GetTextMapPropagator().Extract(savedCtx, propagation.HeaderCarrier(request.Header))
+			opts := []trace.SpanStartOption{
+				trace.WithAttributes(semconv.NetAttributesFromHTTPRequest("tcp", request)...),
+				trace.WithAttributes(semconv.EndUserAttributesFromHTTPRequest(request)...),
+				trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest(service, "", request)...),
+				trace.WithSpanKind(trace.SpanKindServer),
+			}
+			spanName := request.URL.Path
+			ctx, span := tracer.Start(ctx, spanName, opts...)
+			defer span.End()
+
+			request = request.WithContext(ctx)
+			c.SetRequest(request)
+
+			start := time.Now()
+			if instr != nil {
+				instr.activeRequests.Add(ctx, 1)
+				defer instr.activeRequests.Add(ctx, -1)
+			}
+
+			err := next(c)
+
+			route := cfg.RouteExtractor(c)
+			status := c.Response().Status
+			if err != nil {
+				if echoErr, ok := err.(*echo.HTTPError); ok {
+					status = echoErr.Code
+				}
+				span.RecordError(err)
+			}
+
+			scheme := "http"
+			if request.TLS != nil {
+				scheme = "https"
+			}
+
+			attrs := []attribute.KeyValue{
+				semconv.HTTPMethodKey.String(request.Method),
+				semconv.HTTPRouteKey.String(route),
+				semconv.HTTPStatusCodeKey.Int(status),
+				semconv.HTTPSchemeKey.String(scheme),
+			}
+
+			span.SetName(route)
+			span.SetAttributes(attribute.Key("http.route").String(route))
+			spanStatus, spanMessage := semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(status, trace.SpanKindServer)
+			span.SetStatus(spanStatus, spanMessage)
+			if spanStatus == codes.Error {
+				span.SetAttributes(attribute.Key("http.error").String(fmt.Sprintf("%d", status)))
+			}
+
+			if instr != nil {
+				duration := float64(time.Since(start)) / float64(time.Millisecond)
+				instr.requestDuration.Record(ctx, duration, metric.WithAttributes(attrs...))
+				if request.ContentLength >= 0 {
+					instr.requestSize.Record(ctx, request.ContentLength, metric.WithAttributes(attrs...))
+				}
+				instr.responseSize.Record(ctx, c.Response().Size, metric.WithAttributes(attrs...))
+			}
+
+			return err
+		}
+	}
+}
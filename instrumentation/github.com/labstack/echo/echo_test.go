@@ -0,0 +1,81 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package echo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+)
+
+func TestMiddlewareRecordsServerMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	e := echo.New()
+	e.Use(Middleware("test-service", WithMeterProvider(provider)))
+	e.GET("/users/:id", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/123", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var data metricdata.ResourceMetrics
+	err := reader.Collect(req.Context(), &data)
+	require.NoError(t, err)
+	require.NotEmpty(t, data.ScopeMetrics)
+	require.NotEmpty(t, data.ScopeMetrics[0].Metrics)
+
+	histogram, ok := data.ScopeMetrics[0].Metrics[0].Data.(metricdata.Histogram[float64])
+	require.True(t, ok, "expected a float64 histogram")
+	require.NotEmpty(t, histogram.DataPoints)
+
+	scheme, ok := histogram.DataPoints[0].Attributes.Value(semconv.HTTPSchemeKey)
+	require.True(t, ok, "expected http.scheme attribute to be set")
+	assert.Equal(t, "http", scheme.AsString())
+}
+
+func TestDefaultRouteAttributeExtractorUsesMatchedRoute(t *testing.T) {
+	e := echo.New()
+	e.GET("/users/:id", func(c echo.Context) error {
+		assert.Equal(t, "/users/:id", defaultRouteAttributeExtractor(c))
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+}
+
+func TestWithRouteAttributeExtractorOverride(t *testing.T) {
+	cfg := config{}
+	WithRouteAttributeExtractor(func(c echo.Context) string {
+		return "custom"
+	})(&cfg)
+
+	require.NotNil(t, cfg.RouteExtractor)
+}
@@ -0,0 +1,375 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mongo instruments go.mongodb.org/mongo-driver/mongo.
+package mongo // import "go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo"
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName = instrumentationName
+	meterName  = instrumentationName
+)
+
+// Monitor bundles the event.CommandMonitor and event.PoolMonitor that
+// instrument a mongo-driver client. Install both on the client's options so
+// that command spans, command-duration metrics, and connection-pool metrics
+// are all recorded consistently.
+type Monitor struct {
+	CommandMonitor *event.CommandMonitor
+	PoolMonitor    *event.PoolMonitor
+}
+
+// instruments holds the metric instruments shared by every operation and
+// pool event a Monitor observes.
+type instruments struct {
+	operationDuration    metric.Float64Histogram
+	connectionsUsage     metric.Int64UpDownCounter
+	connectionsMax       metric.Int64UpDownCounter
+	connectionsPending   metric.Int64UpDownCounter
+	connectionCreateTime metric.Float64Histogram
+}
+
+func newInstruments(meter metric.Meter) (*instruments, error) {
+	operationDuration, err := meter.Float64Histogram(
+		"db.client.operations.duration",
+		metric.WithDescription("Duration of mongo-driver operations, in milliseconds."),
+		metric.WithUnit("ms"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	connectionsUsage, err := meter.Int64UpDownCounter(
+		"db.client.connections.usage",
+		metric.WithDescription("The number of connections in a pool, partitioned by used/idle state."),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	connectionsMax, err := meter.Int64UpDownCounter(
+		"db.client.connections.max",
+		metric.WithDescription("The maximum number of open connections allowed in a pool."),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	connectionsPending, err := meter.Int64UpDownCounter(
+		"db.client.connections.pending_requests",
+		metric.WithDescription("The number of connection requests waiting for an available connection in a pool."),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	connectionCreateTime, err := meter.Float64Histogram(
+		"db.client.connections.create_time",
+		metric.WithDescription("Duration to create a new connection in a pool, in milliseconds."),
+		metric.WithUnit("ms"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &instruments{
+		operationDuration:    operationDuration,
+		connectionsUsage:     connectionsUsage,
+		connectionsMax:       connectionsMax,
+		connectionsPending:   connectionsPending,
+		connectionCreateTime: connectionCreateTime,
+	}, nil
+}
+
+// serverAddress splits a mongo-driver "host:port" address string into its
+// host and port attribute values.
+func serverAddress(addr string) (string, string) {
+	host := addr
+	port := ""
+	if h, p, err := splitHostPort(host); err == nil {
+		host, port = h, p
+	}
+	return host, port
+}
+
+func splitHostPort(addr string) (host, port string, err error) {
+	idx := -1
+	for i := len(addr) - 1; i >= 0; i-- {
+		if addr[i] == ':' {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return addr, "", errNoPort
+	}
+	return addr[:idx], addr[idx+1:], nil
+}
+
+var errNoPort = &noPortError{}
+
+type noPortError struct{}
+
+func (*noPortError) Error() string { return "address has no port" }
+
+// NewMonitor creates a new Monitor that instruments command execution with
+// spans and db.client.operations.duration, and records connection pool
+// metrics per server from the pool events' Address.
+func NewMonitor(opts ...Option) *Monitor {
+	cfg := config{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.TracerProvider == nil {
+		cfg.TracerProvider = otel.GetTracerProvider()
+	}
+	if cfg.MeterProvider == nil {
+		cfg.MeterProvider = otel.GetMeterProvider()
+	}
+
+	tracer := cfg.TracerProvider.Tracer(tracerName)
+	meter := cfg.MeterProvider.Meter(meterName)
+
+	instr, err := newInstruments(meter)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	m := &monitor{
+		cfg:         cfg,
+		tracer:      tracer,
+		instr:       instr,
+		spans:       make(map[int64]monitorSpan),
+		connStarted: make(map[uint64]time.Time),
+		connState:   make(map[uint64]string),
+	}
+
+	return &Monitor{
+		CommandMonitor: m.commandMonitor(),
+		PoolMonitor:    m.poolMonitor(),
+	}
+}
+
+type monitorSpan struct {
+	span       trace.Span
+	started    time.Time
+	dbName     string
+	collection string
+}
+
+// monitor is the unexported implementation backing the event.CommandMonitor
+// and event.PoolMonitor returned by NewMonitor. Pool metrics are attributed
+// to the server.address/server.port pair parsed from each pool event's
+// Address; command durations to the pair parsed from the finished command's
+// ConnectionID.
+type monitor struct {
+	cfg    config
+	tracer trace.Tracer
+	instr  *instruments
+
+	mu          sync.Mutex
+	spans       map[int64]monitorSpan
+	connStarted map[uint64]time.Time
+	connState   map[uint64]string
+}
+
+func (m *monitor) commandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: m.commandStarted,
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			m.commandFinished(ctx, evt.CommandFinishedEvent, nil)
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			m.commandFinished(ctx, evt.CommandFinishedEvent, errCommandFailed(evt.Failure))
+		},
+	}
+}
+
+func errCommandFailed(reason string) error {
+	return &commandError{reason: reason}
+}
+
+type commandError struct{ reason string }
+
+func (e *commandError) Error() string { return e.reason }
+
+func (m *monitor) commandStarted(ctx context.Context, evt *event.CommandStartedEvent) {
+	attrs := []attribute.KeyValue{
+		attribute.String("db.operation", evt.CommandName),
+		attribute.String("db.name", evt.DatabaseName),
+	}
+	if collection, ok := collectionName(evt); ok {
+		attrs = append(attrs, attribute.String("db.mongodb.collection", collection))
+	}
+
+	_, span := m.tracer.Start(ctx, evt.CommandName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+
+	collection, _ := collectionName(evt)
+
+	m.mu.Lock()
+	m.spans[evt.RequestID] = monitorSpan{
+		span:       span,
+		started:    time.Now(),
+		dbName:     evt.DatabaseName,
+		collection: collection,
+	}
+	m.mu.Unlock()
+}
+
+func (m *monitor) commandFinished(ctx context.Context, evt event.CommandFinishedEvent, failure error) {
+	m.mu.Lock()
+	ms, ok := m.spans[evt.RequestID]
+	delete(m.spans, evt.RequestID)
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+	defer ms.span.End()
+
+	statusCode := codes.Ok
+	if failure != nil {
+		statusCode = codes.Error
+		ms.span.RecordError(failure)
+	}
+	ms.span.SetStatus(statusCode, "")
+
+	if m.instr == nil {
+		return
+	}
+
+	// ConnectionID has the form "host:port[-N]"; drop the "[-N]" suffix so
+	// that the port parses.
+	connAddr := evt.ConnectionID
+	if i := strings.LastIndex(connAddr, "[-"); i >= 0 {
+		connAddr = connAddr[:i]
+	}
+	host, port := serverAddress(connAddr)
+	attrs := []attribute.KeyValue{
+		attribute.String("db.operation", evt.CommandName),
+		attribute.String("db.name", ms.dbName),
+		attribute.String("server.address", host),
+		attribute.String("otel.status_code", statusCode.String()),
+	}
+	if ms.collection != "" {
+		attrs = append(attrs, attribute.String("db.mongodb.collection", ms.collection))
+	}
+	if port != "" {
+		if p, err := strconv.Atoi(port); err == nil {
+			attrs = append(attrs, attribute.Int("server.port", p))
+		}
+	}
+
+	duration := float64(evt.DurationNanos) / float64(time.Millisecond)
+	m.instr.operationDuration.Record(ctx, duration, metric.WithAttributes(attrs...))
+}
+
+func collectionName(evt *event.CommandStartedEvent) (string, bool) {
+	coll, ok := evt.Command.Lookup(evt.CommandName).StringValueOK()
+	return coll, ok
+}
+
+func (m *monitor) poolMonitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: m.poolEvent,
+	}
+}
+
+func (m *monitor) poolEvent(evt *event.PoolEvent) {
+	if m.instr == nil {
+		return
+	}
+
+	ctx := context.Background()
+	host, port := serverAddress(evt.Address)
+	serverAttrs := []attribute.KeyValue{attribute.String("server.address", host)}
+	if port != "" {
+		if p, err := strconv.Atoi(port); err == nil {
+			serverAttrs = append(serverAttrs, attribute.Int("server.port", p))
+		}
+	}
+
+	switch evt.Type {
+	case event.PoolCreated:
+		if evt.PoolOptions != nil {
+			m.instr.connectionsMax.Add(ctx, int64(evt.PoolOptions.MaxPoolSize), metric.WithAttributes(serverAttrs...))
+		}
+	case event.PoolClosedEvent:
+		if evt.PoolOptions != nil {
+			m.instr.connectionsMax.Add(ctx, -int64(evt.PoolOptions.MaxPoolSize), metric.WithAttributes(serverAttrs...))
+		}
+	case event.ConnectionCreated:
+		m.mu.Lock()
+		m.connStarted[evt.ConnectionID] = time.Now()
+		m.mu.Unlock()
+	case event.ConnectionReady:
+		m.mu.Lock()
+		started, ok := m.connStarted[evt.ConnectionID]
+		delete(m.connStarted, evt.ConnectionID)
+		m.mu.Unlock()
+		if ok {
+			duration := float64(time.Since(started)) / float64(time.Millisecond)
+			m.instr.connectionCreateTime.Record(ctx, duration, metric.WithAttributes(serverAttrs...))
+		}
+	case event.GetStarted:
+		m.instr.connectionsPending.Add(ctx, 1, metric.WithAttributes(serverAttrs...))
+	case event.GetSucceeded:
+		m.instr.connectionsPending.Add(ctx, -1, metric.WithAttributes(serverAttrs...))
+		usedAttrs := append(append([]attribute.KeyValue{}, serverAttrs...), attribute.String("state", "used"))
+		m.instr.connectionsUsage.Add(ctx, 1, metric.WithAttributes(usedAttrs...))
+		m.mu.Lock()
+		m.connState[evt.ConnectionID] = "used"
+		m.mu.Unlock()
+	case event.GetFailed:
+		m.instr.connectionsPending.Add(ctx, -1, metric.WithAttributes(serverAttrs...))
+	case event.ConnectionReturned:
+		usedAttrs := append(append([]attribute.KeyValue{}, serverAttrs...), attribute.String("state", "used"))
+		m.instr.connectionsUsage.Add(ctx, -1, metric.WithAttributes(usedAttrs...))
+		idleAttrs := append(append([]attribute.KeyValue{}, serverAttrs...), attribute.String("state", "idle"))
+		m.instr.connectionsUsage.Add(ctx, 1, metric.WithAttributes(idleAttrs...))
+		m.mu.Lock()
+		m.connState[evt.ConnectionID] = "idle"
+		m.mu.Unlock()
+	case event.ConnectionClosed:
+		// Only decrement connectionsUsage for a connection that was
+		// actually counted in it, i.e. one that was checked out at least
+		// once; a connection closed before its first GetSucceeded never
+		// incremented either state.
+		m.mu.Lock()
+		state, tracked := m.connState[evt.ConnectionID]
+		delete(m.connState, evt.ConnectionID)
+		m.mu.Unlock()
+		if tracked {
+			attrs := append(append([]attribute.KeyValue{}, serverAttrs...), attribute.String("state", state))
+			m.instr.connectionsUsage.Add(ctx, -1, metric.WithAttributes(attrs...))
+		}
+	}
+}
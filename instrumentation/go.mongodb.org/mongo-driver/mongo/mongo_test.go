@@ -0,0 +1,40 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerAddressSplitsHostAndPort(t *testing.T) {
+	host, port := serverAddress("localhost:27017")
+	assert.Equal(t, "localhost", host)
+	assert.Equal(t, "27017", port)
+}
+
+func TestServerAddressWithoutPort(t *testing.T) {
+	host, port := serverAddress("localhost")
+	assert.Equal(t, "localhost", host)
+	assert.Equal(t, "", port)
+}
+
+func TestNewMonitorInstallsBothMonitors(t *testing.T) {
+	m := NewMonitor()
+	require.NotNil(t, m.CommandMonitor)
+	require.NotNil(t, m.PoolMonitor)
+}
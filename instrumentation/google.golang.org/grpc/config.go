@@ -0,0 +1,81 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc // import "go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc"
+
+import (
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc"
+
+// config is the shared configuration for the client and server
+// interceptors.
+type config struct {
+	TracerProvider   trace.TracerProvider
+	MeterProvider    metric.MeterProvider
+	csmObservability bool
+}
+
+// Option applies configuration to the client/server interceptors.
+type Option func(*config)
+
+// WithTracerProvider specifies a tracer provider to use for creating a
+// tracer. If none is specified, the global provider is used.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(cfg *config) {
+		if provider != nil {
+			cfg.TracerProvider = provider
+		}
+	}
+}
+
+// WithMeterProvider specifies a meter provider to use for creating a meter.
+// If none is specified, the global provider is used.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(cfg *config) {
+		if provider != nil {
+			cfg.MeterProvider = provider
+		}
+	}
+}
+
+// WithCSMObservability enables Cloud Service Mesh (CSM) observability:
+// spans and metrics produced by the interceptor are decorated with the
+// workload's service-mesh identity, and, for both client and server
+// interceptors, with the identity of the remote workload once it is learned
+// from the peer. This is
+// only meaningful for workloads running inside a mesh managed by CSM; on any
+// other workload the local csm.* labels resolve to "unknown" and no remote
+// labels are ever attached.
+//
+// The remote identity is learned from this package's own simplified
+// x-envoy-peer-metadata encoding, not the google.protobuf.Struct format a
+// real Envoy sidecar uses, so remote labels are only populated when the peer
+// is also running this interceptor; see the commentary on peerMetadataHeader
+// in csm.go for details.
+func WithCSMObservability() Option {
+	return func(cfg *config) {
+		cfg.csmObservability = true
+	}
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
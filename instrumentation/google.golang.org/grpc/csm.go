@@ -0,0 +1,237 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc // import "go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc"
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// peerMetadataHeader is the gRPC metadata key CSM-aware proxies and peers
+// exchange workload identity on, base64-encoded, in the initial request and
+// its response.
+//
+// Real Envoy/CSM metadata exchange (the "envoy.wasm.metadata_exchange"
+// filter that Cloud Service Mesh sidecars run) serializes this payload as a
+// google.protobuf.Struct, not the flat "KEY=value;KEY=value" string that
+// parseWireFields/encodeWireFields below produce. That's a deliberate
+// simplification, not an oversight: this package only needs to exchange
+// identity between two workloads that are both running this interceptor, so
+// a protobuf dependency for a handful of string fields didn't seem worth it.
+// The consequence is that this header is NOT wire-compatible with a real
+// Envoy sidecar or any other CSM peer that isn't also running this
+// interceptor - such a peer will fail to parse it and skip emitting
+// csm.remote_workload_* labels, the same as if the header were absent. A
+// workload that needs real interop must replace parseWireFields and
+// encodeWireFields with a google.protobuf.Struct encoding.
+const peerMetadataHeader = "x-envoy-peer-metadata"
+
+const gceMetadataFlavorHeader = "Metadata-Flavor"
+
+// Wire field names used in the x-envoy-peer-metadata payload. A peer decodes
+// these into the corresponding csm.remote_workload_* attributes; see
+// remoteCSMLabelsFromHeader for the mapping.
+const (
+	wireWorkloadName     = "WORKLOAD_NAME"
+	wireCanonicalService = "CANONICAL_SERVICE"
+	wireNamespaceName    = "NAMESPACE_NAME"
+	wireClusterName      = "CLUSTER_NAME"
+	wireLocation         = "LOCATION"
+)
+
+// csmResolver resolves and caches this workload's local CSM identity,
+// probing the environment and, failing that, the GCE/GKE metadata server
+// exactly once.
+type csmResolver struct {
+	once     sync.Once
+	local    []attribute.KeyValue
+	wireData string
+}
+
+func newCSMResolver() *csmResolver {
+	return &csmResolver{}
+}
+
+// localLabels returns the local csm.* span/metric attributes for this
+// workload. The values never change for the lifetime of the process, so
+// they are resolved once and cached.
+func (r *csmResolver) localLabels() []attribute.KeyValue {
+	r.once.Do(r.resolve)
+	return r.local
+}
+
+// wirePayload returns the base64-encoded x-envoy-peer-metadata payload this
+// workload sends to its peers.
+func (r *csmResolver) wirePayload() string {
+	r.once.Do(r.resolve)
+	return r.wireData
+}
+
+func (r *csmResolver) resolve() {
+	fields := resolveIdentityFields()
+
+	r.local = []attribute.KeyValue{
+		attribute.String("csm.workload_canonical_service", orUnknown(fields[wireCanonicalService])),
+		attribute.String("csm.mesh_id", orUnknown(os.Getenv("MESH_ID"))),
+	}
+	r.wireData = encodeWireFields(fields)
+}
+
+// resolveIdentityFields gathers the fields CSM-aware peers exchange on
+// x-envoy-peer-metadata, preferring explicit environment configuration and
+// falling back to a GCE/GKE metadata-server probe.
+func resolveIdentityFields() map[string]string {
+	fields := map[string]string{
+		wireWorkloadName:     os.Getenv("CSM_WORKLOAD_NAME"),
+		wireCanonicalService: os.Getenv("CSM_CANONICAL_SERVICE_NAME"),
+		wireNamespaceName:    os.Getenv("POD_NAMESPACE"),
+	}
+	if fields[wireCanonicalService] == "" {
+		fields[wireCanonicalService] = fields[wireWorkloadName]
+	}
+	if fields[wireWorkloadName] == "" {
+		fields[wireWorkloadName] = gceMetadataAttribute("instance/name")
+	}
+	if fields[wireCanonicalService] == "" {
+		fields[wireCanonicalService] = fields[wireWorkloadName]
+	}
+	fields[wireClusterName] = gceMetadataAttribute("instance/attributes/cluster-name")
+	fields[wireLocation] = gceMetadataAttribute("instance/zone")
+
+	for k, v := range fields {
+		if v == "" {
+			delete(fields, k)
+		}
+	}
+	return fields
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+const gceMetadataHost = "http://metadata.google.internal/computeMetadata/v1/"
+
+var gceMetadataClient = http.Client{Timeout: 2 * time.Second}
+
+// gceMetadataAttribute probes the GCE/GKE metadata server for a single
+// attribute, returning "" if the workload is not running on GCE/GKE or the
+// probe otherwise fails. Failures here are expected and silent: most
+// workloads running this instrumentation are not inside CSM at all.
+func gceMetadataAttribute(path string) string {
+	req, err := http.NewRequest(http.MethodGet, gceMetadataHost+path, nil)
+	if err != nil {
+		return ""
+	}
+	req.Header.Set(gceMetadataFlavorHeader, "Google")
+
+	resp, err := gceMetadataClient.Do(req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ""
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// remoteCSMLabelsFromHeader decodes an x-envoy-peer-metadata header value
+// into csm.remote_workload_* attributes. It returns ok=false if the header
+// is absent or malformed, in which case no remote labels should be
+// attached.
+func remoteCSMLabelsFromHeader(value string) (attrs []attribute.KeyValue, ok bool) {
+	if value == "" {
+		return nil, false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, false
+	}
+
+	fields := parseWireFields(string(decoded))
+	if len(fields) == 0 {
+		return nil, false
+	}
+
+	add := func(key, field string) {
+		if v, ok := fields[field]; ok {
+			attrs = append(attrs, attribute.String(key, v))
+		}
+	}
+	add("csm.remote_workload_type", wireWorkloadName)
+	add("csm.remote_workload_canonical_service", wireCanonicalService)
+	add("csm.remote_workload_namespace_name", wireNamespaceName)
+	add("csm.remote_workload_cluster_name", wireClusterName)
+	add("csm.remote_workload_location", wireLocation)
+
+	return attrs, len(attrs) > 0
+}
+
+// parseWireFields parses this package's simplified "KEY=value;KEY=value"
+// wire format for the peer-metadata header payload; see the commentary on
+// peerMetadataHeader for how this differs from real Envoy/CSM's
+// google.protobuf.Struct encoding.
+func parseWireFields(s string) map[string]string {
+	fields := make(map[string]string)
+	for _, part := range strings.Split(s, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		fields[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return fields
+}
+
+// encodeWireFields encodes this workload's identity fields into this
+// package's simplified x-envoy-peer-metadata wire format (see
+// peerMetadataHeader), to be sent to the peer on the initial request
+// (clients) or its response (servers).
+func encodeWireFields(fields map[string]string) string {
+	order := []string{wireWorkloadName, wireCanonicalService, wireNamespaceName, wireClusterName, wireLocation}
+
+	var b strings.Builder
+	first := true
+	for _, k := range order {
+		v, ok := fields[k]
+		if !ok {
+			continue
+		}
+		if !first {
+			b.WriteByte(';')
+		}
+		first = false
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(v)
+	}
+	return base64.StdEncoding.EncodeToString([]byte(b.String()))
+}
@@ -0,0 +1,50 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestRemoteCSMLabelsFromHeaderDecodesKnownFields(t *testing.T) {
+	encoded := encodeWireFields(map[string]string{
+		wireWorkloadName:     "gke",
+		wireCanonicalService: "checkout",
+	})
+
+	attrs, ok := remoteCSMLabelsFromHeader(encoded)
+	assert.True(t, ok)
+	assert.Contains(t, attrs, attribute.String("csm.remote_workload_type", "gke"))
+	assert.Contains(t, attrs, attribute.String("csm.remote_workload_canonical_service", "checkout"))
+}
+
+func TestRemoteCSMLabelsFromHeaderEmpty(t *testing.T) {
+	_, ok := remoteCSMLabelsFromHeader("")
+	assert.False(t, ok)
+}
+
+func TestRemoteCSMLabelsFromHeaderMalformedBase64(t *testing.T) {
+	_, ok := remoteCSMLabelsFromHeader("not-base64!!!")
+	assert.False(t, ok)
+}
+
+func TestOrUnknown(t *testing.T) {
+	assert.Equal(t, "unknown", orUnknown(""))
+	assert.Equal(t, "foo", orUnknown("foo"))
+}
@@ -0,0 +1,278 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package grpc instruments google.golang.org/grpc.
+package grpc // import "go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc"
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var csmResolverSingleton = newCSMResolver()
+
+// interceptorConfig is the resolved, ready-to-use form of config: providers
+// defaulted and a tracer and duration histogram already created.
+type interceptorConfig struct {
+	tracer trace.Tracer
+	instr  *instruments
+	csm    bool
+}
+
+// resolve defaults cfg's providers and builds the tracer and duration
+// histogram an interceptor needs. durationMetricName distinguishes the
+// client-side and server-side duration histograms, since a single config
+// backs both.
+func resolve(opts []Option, durationMetricName string) interceptorConfig {
+	cfg := newConfig(opts)
+	if cfg.TracerProvider == nil {
+		cfg.TracerProvider = otel.GetTracerProvider()
+	}
+	if cfg.MeterProvider == nil {
+		cfg.MeterProvider = otel.GetMeterProvider()
+	}
+
+	meter := cfg.MeterProvider.Meter(instrumentationName)
+	instr, err := newInstruments(meter, durationMetricName)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return interceptorConfig{
+		tracer: cfg.TracerProvider.Tracer(instrumentationName),
+		instr:  instr,
+		csm:    cfg.csmObservability,
+	}
+}
+
+// recordDuration records a completed call's duration, decorated with this
+// workload's local CSM labels and, if known, the peer's remote CSM labels.
+// It is a no-op if the duration histogram failed to initialize.
+func recordDuration(ctx context.Context, cfg interceptorConfig, method string, start time.Time, remote []attribute.KeyValue) {
+	if cfg.instr == nil {
+		return
+	}
+	attrs := append([]attribute.KeyValue{attribute.String("rpc.method", method)}, cfg.localAttributes()...)
+	attrs = append(attrs, remote...)
+
+	elapsed := float64(time.Since(start)) / float64(time.Millisecond)
+	cfg.instr.duration.Record(ctx, elapsed, metric.WithAttributes(attrs...))
+}
+
+// localAttributes returns this workload's CSM labels to attach to a call's
+// span, or nil when CSM observability is not enabled.
+func (c interceptorConfig) localAttributes() []attribute.KeyValue {
+	if !c.csm {
+		return nil
+	}
+	return csmResolverSingleton.localLabels()
+}
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor suitable for
+// use with grpc.WithChainUnaryInterceptor. It records a call span and an
+// "rpc.client.duration" histogram for every call. When WithCSMObservability
+// is set, both the span and the duration histogram are decorated with this
+// workload's local CSM labels, which are also sent to the server on the
+// outgoing x-envoy-peer-metadata header; if the server echoes its own
+// peer-metadata header back, the resulting remote labels are attached too.
+func UnaryClientInterceptor(opts ...Option) grpc.UnaryClientInterceptor {
+	cfg := resolve(opts, "rpc.client.duration")
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		start := time.Now()
+		ctx, span, trailer := startClientSpan(ctx, cfg, method)
+		err := invoker(ctx, method, req, reply, cc, append(callOpts, grpc.Trailer(trailer))...)
+		finishClientSpan(ctx, span, cfg, method, start, *trailer, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor returns a grpc.StreamClientInterceptor with the
+// same span, metric and CSM observability behavior as UnaryClientInterceptor.
+func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
+	cfg := resolve(opts, "rpc.client.duration")
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		ctx, span, trailer := startClientSpan(ctx, cfg, method)
+		stream, err := streamer(ctx, desc, cc, method, append(callOpts, grpc.Trailer(trailer))...)
+		if err != nil {
+			finishClientSpan(ctx, span, cfg, method, start, *trailer, err)
+			return nil, err
+		}
+		return &csmTrackingClientStream{ClientStream: stream, ctx: ctx, span: span, cfg: cfg, method: method, start: start, trailer: trailer}, nil
+	}
+}
+
+func startClientSpan(ctx context.Context, cfg interceptorConfig, method string) (context.Context, trace.Span, *metadata.MD) {
+	ctx, span := cfg.tracer.Start(ctx, method, trace.WithSpanKind(trace.SpanKindClient))
+	span.SetAttributes(cfg.localAttributes()...)
+
+	if cfg.csm {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		md = md.Copy()
+		md.Set(peerMetadataHeader, csmResolverSingleton.wirePayload())
+		ctx = metadata.NewOutgoingContext(ctx, md)
+	}
+
+	var trailer metadata.MD
+	return ctx, span, &trailer
+}
+
+func finishClientSpan(ctx context.Context, span trace.Span, cfg interceptorConfig, method string, start time.Time, trailer metadata.MD, err error) {
+	var remote []attribute.KeyValue
+	if cfg.csm {
+		if r, ok := remoteCSMLabelsFromHeader(firstValue(trailer, peerMetadataHeader)); ok {
+			span.SetAttributes(r...)
+			remote = r
+		}
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+	span.End()
+
+	recordDuration(ctx, cfg, method, start, remote)
+}
+
+func firstValue(md metadata.MD, key string) string {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// csmTrackingClientStream wraps a grpc.ClientStream so the client span can
+// be finished, with any remote CSM labels attached, once the stream
+// actually completes rather than when it is first established.
+type csmTrackingClientStream struct {
+	grpc.ClientStream
+	ctx     context.Context
+	span    trace.Span
+	cfg     interceptorConfig
+	method  string
+	start   time.Time
+	trailer *metadata.MD
+	done    bool
+}
+
+func (s *csmTrackingClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil && !s.done {
+		s.done = true
+		finishClientSpan(s.ctx, s.span, s.cfg, s.method, s.start, *s.trailer, realStreamErr(err))
+	}
+	return err
+}
+
+// realStreamErr treats io.EOF as a successful stream completion rather than
+// a failure.
+func realStreamErr(err error) error {
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor. It records a
+// call span and an "rpc.server.duration" histogram for every call. When
+// WithCSMObservability is set, it decodes the caller's peer-metadata header
+// into remote labels attached to both the span and the duration histogram,
+// and echoes this workload's own labels back to the caller on the response
+// trailer.
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	cfg := resolve(opts, "rpc.server.duration")
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		ctx, span, remote := startServerSpan(ctx, cfg, info.FullMethod)
+		defer finishServerSpan(ctx, span, cfg, info.FullMethod, start, remote)
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else {
+			span.SetStatus(codes.Ok, "")
+		}
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor with the
+// same span, metric and CSM observability behavior as UnaryServerInterceptor.
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	cfg := resolve(opts, "rpc.server.duration")
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx, span, remote := startServerSpan(ss.Context(), cfg, info.FullMethod)
+		defer finishServerSpan(ctx, span, cfg, info.FullMethod, start, remote)
+
+		err := handler(srv, &serverStreamWithContext{ServerStream: ss, ctx: ctx})
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else {
+			span.SetStatus(codes.Ok, "")
+		}
+		return err
+	}
+}
+
+// startServerSpan also returns the caller's remote CSM labels, if any were
+// decoded from the incoming peer-metadata header, so the caller can attach
+// them to the duration metric recorded once the call finishes.
+func startServerSpan(ctx context.Context, cfg interceptorConfig, method string) (context.Context, trace.Span, []attribute.KeyValue) {
+	ctx, span := cfg.tracer.Start(ctx, method, trace.WithSpanKind(trace.SpanKindServer))
+	span.SetAttributes(cfg.localAttributes()...)
+
+	var remote []attribute.KeyValue
+	if cfg.csm {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if r, ok := remoteCSMLabelsFromHeader(firstValue(md, peerMetadataHeader)); ok {
+				span.SetAttributes(r...)
+				remote = r
+			}
+		}
+		_ = grpc.SetTrailer(ctx, metadata.Pairs(peerMetadataHeader, csmResolverSingleton.wirePayload()))
+	}
+
+	return ctx, span, remote
+}
+
+func finishServerSpan(ctx context.Context, span trace.Span, cfg interceptorConfig, method string, start time.Time, remote []attribute.KeyValue) {
+	span.End()
+	recordDuration(ctx, cfg, method, start, remote)
+}
+
+type serverStreamWithContext struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStreamWithContext) Context() context.Context {
+	return s.ctx
+}
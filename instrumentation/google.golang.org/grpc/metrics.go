@@ -0,0 +1,40 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc // import "go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc"
+
+import (
+	"go.opentelemetry.io/otel/metric"
+)
+
+// instruments bundles the metrics an interceptor records. They are created
+// once per interceptor and shared across every call it handles.
+type instruments struct {
+	duration metric.Float64Histogram
+}
+
+// newInstruments creates the duration histogram an interceptor records,
+// named after the side of the call it instruments ("rpc.client.duration" or
+// "rpc.server.duration").
+func newInstruments(meter metric.Meter, durationMetricName string) (*instruments, error) {
+	duration, err := meter.Float64Histogram(
+		durationMetricName,
+		metric.WithDescription("Measures the duration of an RPC call."),
+		metric.WithUnit("ms"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &instruments{duration: duration}, nil
+}